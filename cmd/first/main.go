@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -13,107 +14,125 @@ import (
 
 type RootParams struct{}
 type ResourceParams struct {
+	Root string `json:"root,omitempty"` // optional; selects a named workspace
 	Path string `json:"path,omitempty"` // optional; defaults to root
 }
 
 func main() {
-	// Determine root directory:
-	// 1) MCP_FS_ROOT env var, else 2) current working directory.
-	rootDir := os.Getenv("MCP_FS_ROOT")
-	if rootDir == "" {
-		wd, err := os.Getwd()
-		if err != nil {
-			log.Fatalf("cannot determine working directory: %v", err)
-		}
-		rootDir = wd
+	roots := flag.String("roots", "", "comma separated name=path workspace roots (overrides MCP_FS_ROOTS)")
+	flag.Parse()
+
+	// Determine the configured workspaces: the -roots flag replaces
+	// MCP_FS_ROOTS (name=path,name=path,...); MCP_FS_ROOT (single
+	// unnamed root) is added alongside them as "default", replacing any
+	// MCP_FS_ROOTS entry of that name; with neither set, the current
+	// working directory is used as "default".
+	if *roots != "" {
+		os.Setenv("MCP_FS_ROOTS", *roots)
+	}
+	workspaces, err := loadWorkspaces()
+	if err != nil {
+		log.Fatalf("cannot configure roots: %v", err)
 	}
-	rootDir = absOrDie(rootDir)
 
-	// Helper to resolve paths under root, blocking traversal outside root.
-	resolveWithinRoot := func(p string) (string, error) {
-		if p == "" || p == "." {
-			p = ""
-		}
-		joined := filepath.Join(rootDir, p)
-		abs, err := filepath.Abs(joined)
-		if err != nil {
-			return "", err
-		}
-		rel, err := filepath.Rel(rootDir, abs)
-		if err != nil {
-			return "", err
-		}
-		if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
-			return "", fmt.Errorf("path outside root: %q", p)
-		}
-		return abs, nil
+	// Load the tool/path policy from MCP_FS_POLICY, defaulting to
+	// read-only when no policy file is configured, and attach it to
+	// every workspace so the shared resolver enforces it.
+	policy, err := loadPolicy()
+	if err != nil {
+		log.Fatalf("cannot load policy: %v", err)
 	}
+	workspaces.SetPolicy(policy)
+
+	// The resource watcher is wired into the server's subscribe/unsubscribe
+	// handlers at construction, but needs the *mcp.Server back-reference to
+	// emit notifications — set once srv exists below.
+	rw := newResourceWatcher(nil, workspaces)
 
 	// Init server
 	srv := mcp.NewServer(&mcp.Implementation{
 		Name:    "FileSystem MCP",
-		Version: "0.2.0",
-	}, nil)
-
-	// Tool: list_roots  — show the single configured root
-	mcp.AddTool(srv, &mcp.Tool{
-		Name:        "list_roots",
-		Description: "Show the configured root directory for file browsing",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, _ RootParams) (*mcp.CallToolResult, interface{}, error) {
-		text := fmt.Sprintf("Root: %s", rootDir)
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: text}},
-		}, []string{rootDir}, nil
+		Version: "0.6.0",
+	}, &mcp.ServerOptions{
+		SubscribeHandler:   rw.subscribe,
+		UnsubscribeHandler: rw.unsubscribe,
 	})
+	rw.srv = srv
 
-	// Tool: list_resources — list entries at a path relative to root (or root if empty)
-	mcp.AddTool(srv, &mcp.Tool{
-		Name:        "list_resources",
-		Description: "List files/directories under the configured root. Args: { path?: string }",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args ResourceParams) (*mcp.CallToolResult, interface{}, error) {
-		target, err := resolveWithinRoot(args.Path)
-		if err != nil {
-			return nil, nil, err
-		}
-		entries, err := os.ReadDir(target)
-		if err != nil {
-			return nil, nil, err
-		}
+	// Tool: list_roots — show every configured workspace
+	if policy.ToolEnabled("list_roots") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "list_roots",
+			Description: "List the configured root directories for file browsing, by workspace name",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, _ RootParams) (*mcp.CallToolResult, interface{}, error) {
+			names := workspaces.Names()
+			lines := make([]string, 0, len(names))
+			roots := make([]string, 0, len(names))
+			for _, name := range names {
+				ws, _ := workspaces.Get(name)
+				lines = append(lines, fmt.Sprintf("%s: %s", name, ws.Root))
+				roots = append(roots, ws.Root)
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: strings.Join(lines, "\n")}},
+			}, roots, nil
+		})
+	}
 
-		names := make([]string, 0, len(entries))
-		for _, e := range entries {
-			name := e.Name()
-			if e.IsDir() {
-				name += string(os.PathSeparator)
+	// Tool: list_resources — list entries at a path relative to a workspace root
+	if policy.ToolEnabled("list_resources") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "list_resources",
+			Description: "List files/directories under a configured root. Args: { root?: string, path?: string }. path accepts either a root-relative path or a file:// resource URI.",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args ResourceParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			target, err := ws.Resolve("list_resources", args.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			entries, err := os.ReadDir(target)
+			if err != nil {
+				return nil, nil, err
 			}
-			names = append(names, name)
-		}
 
-		// Human-friendly content + structured result
-		lines := strings.Join(names, "\n")
-		text := fmt.Sprintf("Listing for %s:\n%s", relOrSame(rootDir, target), lines)
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: text}},
-		}, names, nil
-	})
+			type resourceEntry struct {
+				Name string `json:"name"`
+				URI  string `json:"uri"`
+			}
+			names := make([]string, 0, len(entries))
+			resources := make([]resourceEntry, 0, len(entries))
+			for _, e := range entries {
+				name := e.Name()
+				if e.IsDir() {
+					name += string(os.PathSeparator)
+				}
+				names = append(names, name)
+				resources = append(resources, resourceEntry{Name: name, URI: ws.URI(filepath.Join(target, e.Name()))})
+			}
 
-	log.Printf("Starting FileSystem MCP server with root: %s", rootDir)
-	if err := srv.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-		log.Fatal("server error:", err)
+			// Human-friendly content + structured result
+			lines := strings.Join(names, "\n")
+			text := fmt.Sprintf("Listing for %s (root %q):\n%s", ws.RelOrSame(target), ws.Name, lines)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: text}},
+			}, resources, nil
+		})
 	}
-}
 
-func absOrDie(p string) string {
-	abs, err := filepath.Abs(p)
-	if err != nil {
-		log.Fatalf("cannot resolve path %q: %v", p, err)
+	registerFsTools(srv, workspaces, policy)
+	registerSearchTool(srv, workspaces, policy)
+	registerResources(srv, workspaces, rw, policy)
+	registerWatchTools(srv, workspaces, rw, policy)
+
+	if err := rw.Start(); err != nil {
+		log.Fatalf("cannot start filesystem watcher: %v", err)
 	}
-	return abs
-}
 
-func relOrSame(base, p string) string {
-	if r, err := filepath.Rel(base, p); err == nil && r != "." {
-		return r
+	log.Printf("Starting FileSystem MCP server with roots: %s", strings.Join(workspaces.Names(), ", "))
+	if err := srv.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		log.Fatal("server error:", err)
 	}
-	return p
 }
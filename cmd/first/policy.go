@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// readOnlyTools is the set of tools considered safe to expose with no
+// policy file at all: they can only observe the filesystem, never
+// change it.
+var readOnlyTools = map[string]bool{
+	"list_roots":     true,
+	"list_resources": true,
+	"read_file":      true,
+	"search":         true,
+	"watch":          true,
+	"unwatch":        true,
+}
+
+// ToolPolicy restricts a single tool to a set of path globs (matched,
+// doublestar-style, against the path relative to the workspace root).
+// An empty Allow means "any path not explicitly denied".
+type ToolPolicy struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// Policy gates which tools are registered at all and which paths each
+// registered tool may touch. With no policy file, the server defaults
+// to read-only: only readOnlyTools are registered, with no further path
+// restriction.
+type Policy struct {
+	ReadOnly bool                  `json:"-" yaml:"-"`
+	Tools    map[string]ToolPolicy `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// defaultPolicy is used when MCP_FS_POLICY is not set.
+func defaultPolicy() *Policy {
+	return &Policy{ReadOnly: true}
+}
+
+// loadPolicy reads the policy file named by MCP_FS_POLICY, if any. The
+// file may be YAML or JSON; format is inferred from the extension,
+// falling back to JSON-then-YAML if the extension is unrecognized.
+func loadPolicy() (*Policy, error) {
+	path := os.Getenv("MCP_FS_POLICY")
+	if path == "" {
+		return defaultPolicy(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy file %q: %w", path, err)
+	}
+
+	policy := &Policy{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, policy)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, policy)
+	default:
+		if jsonErr := json.Unmarshal(data, policy); jsonErr != nil {
+			err = yaml.Unmarshal(data, policy)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing policy file %q: %w", path, err)
+	}
+	return policy, nil
+}
+
+// ToolEnabled reports whether tool should be registered at all.
+func (p *Policy) ToolEnabled(tool string) bool {
+	if p == nil {
+		return readOnlyTools[tool]
+	}
+	if _, ok := p.Tools[tool]; ok {
+		return true
+	}
+	if p.ReadOnly {
+		return readOnlyTools[tool]
+	}
+	return false
+}
+
+// Check verifies that tool may touch relSlash (the path relative to the
+// workspace root, forward-slash separated). It logs every denial along
+// with the resolved path before returning the error, so denied calls
+// leave an audit trail.
+func (p *Policy) Check(tool, relSlash, resolvedPath string) error {
+	if p == nil {
+		return nil
+	}
+	tp, hasRule := p.Tools[tool]
+	if !hasRule {
+		if p.ReadOnly && readOnlyTools[tool] {
+			return nil
+		}
+		err := fmt.Errorf("tool %q is not enabled by policy", tool)
+		logPolicyDenial(tool, resolvedPath, err)
+		return err
+	}
+	if matchesAny(tp.Deny, relSlash) {
+		err := fmt.Errorf("path %q denied by policy for tool %q", relSlash, tool)
+		logPolicyDenial(tool, resolvedPath, err)
+		return err
+	}
+	if len(tp.Allow) > 0 && !matchesAny(tp.Allow, relSlash) {
+		err := fmt.Errorf("path %q not allowed by policy for tool %q", relSlash, tool)
+		logPolicyDenial(tool, resolvedPath, err)
+		return err
+	}
+	return nil
+}
+
+func matchesAny(globs []string, relSlash string) bool {
+	for _, g := range globs {
+		if ok, _ := doublestar.Match(g, relSlash); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func logPolicyDenial(tool, resolvedPath string, err error) {
+	log.Printf("policy: denied %s on %s: %v", tool, resolvedPath, err)
+}
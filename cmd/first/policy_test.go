@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestDefaultPolicy_IsReadOnly(t *testing.T) {
+	p := defaultPolicy()
+
+	for _, tool := range []string{"list_roots", "list_resources", "read_file", "search", "watch", "unwatch"} {
+		if !p.ToolEnabled(tool) {
+			t.Errorf("ToolEnabled(%q) = false, want true under the default read-only policy", tool)
+		}
+	}
+	for _, tool := range []string{"write_file", "mkdir", "delete", "move"} {
+		if p.ToolEnabled(tool) {
+			t.Errorf("ToolEnabled(%q) = true, want false under the default read-only policy", tool)
+		}
+	}
+}
+
+func TestPolicy_CheckAllowDeny(t *testing.T) {
+	p := &Policy{Tools: map[string]ToolPolicy{
+		"read_file":  {Allow: []string{"**/*.md"}},
+		"write_file": {Deny: []string{"**"}},
+	}}
+
+	if !p.ToolEnabled("read_file") || !p.ToolEnabled("write_file") {
+		t.Fatal("expected both configured tools to be enabled")
+	}
+	if p.ToolEnabled("delete") {
+		t.Fatal("expected an unconfigured tool to be disabled")
+	}
+
+	if err := p.Check("read_file", "docs/readme.md", "/root/docs/readme.md"); err != nil {
+		t.Errorf("Check allowed .md path: %v", err)
+	}
+	if err := p.Check("read_file", "secrets.env", "/root/secrets.env"); err == nil {
+		t.Error("expected Check to reject a path outside the allow glob")
+	}
+	if err := p.Check("write_file", "anything.txt", "/root/anything.txt"); err == nil {
+		t.Error("expected Check to reject a path matched by a deny-all glob")
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMaxSearchResults caps how many entries a single search call
+// returns when the caller doesn't set MaxResults, so a walk over a huge
+// tree can't blow up a single response.
+const defaultMaxSearchResults = 500
+
+type SearchParams struct {
+	Root       string `json:"root,omitempty"`
+	Path       string `json:"path,omitempty"`       // subdirectory to start the walk from; defaults to root
+	Glob       string `json:"glob,omitempty"`       // doublestar glob, matched against the path relative to root
+	MimeType   string `json:"mimeType,omitempty"`   // e.g. "text/plain"; matched against the detected MIME type
+	MaxDepth   int    `json:"maxDepth,omitempty"`   // 0 means unlimited
+	MaxResults int    `json:"maxResults,omitempty"` // 0 means defaultMaxSearchResults
+}
+
+// SearchEntry describes one match returned by the search tool.
+type SearchEntry struct {
+	Path     string    `json:"path"` // relative to the workspace root, forward-slash separated
+	URI      string    `json:"uri"`  // file:// resource URI for the same entry
+	IsDir    bool      `json:"isDir"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"modTime"`
+	MimeType string    `json:"mimeType,omitempty"`
+}
+
+func registerSearchTool(srv *mcp.Server, workspaces *WorkspaceSet, policy *Policy) {
+	if !policy.ToolEnabled("search") {
+		return
+	}
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "search",
+		Description: "Walk a configured root for matching files. Args: { root?, path?, glob?, mimeType?, maxDepth?, maxResults? }",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args SearchParams) (*mcp.CallToolResult, interface{}, error) {
+		ws, err := workspaces.Get(args.Root)
+		if err != nil {
+			return nil, nil, err
+		}
+		start, err := ws.Resolve("search", args.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ignore := loadIgnoreMatcher(ws.Root)
+
+		maxResults := args.MaxResults
+		if maxResults <= 0 {
+			maxResults = defaultMaxSearchResults
+		}
+		startDepth := searchStartDepth(ws.Root, start)
+
+		var entries []SearchEntry
+		truncated := false
+
+		err = filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if len(entries) >= maxResults {
+				truncated = true
+				return filepath.SkipAll
+			}
+
+			rel, relErr := filepath.Rel(ws.Root, path)
+			if relErr != nil {
+				return relErr
+			}
+			if rel == "." {
+				return nil
+			}
+			relSlash := filepath.ToSlash(rel)
+
+			if ignore.Match(relSlash, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if args.MaxDepth > 0 {
+				depth := strings.Count(relSlash, "/") + 1 - startDepth
+				if depth > args.MaxDepth {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+			}
+
+			if args.Glob != "" {
+				matched, err := doublestar.Match(args.Glob, relSlash)
+				if err != nil {
+					return fmt.Errorf("invalid glob %q: %w", args.Glob, err)
+				}
+				if !matched {
+					return nil
+				}
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			entry := SearchEntry{
+				Path:    relSlash,
+				URI:     ws.URI(path),
+				IsDir:   d.IsDir(),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			}
+			if !d.IsDir() {
+				entry.MimeType = detectMimeType(path, info)
+				if args.MimeType != "" && entry.MimeType != args.MimeType {
+					return nil
+				}
+			} else if args.MimeType != "" {
+				return nil
+			}
+
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		text := fmt.Sprintf("Found %d entries under %s", len(entries), ws.RelOrSame(start))
+		if truncated {
+			text += fmt.Sprintf(" (truncated at %d results)", maxResults)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		}, map[string]any{"entries": entries, "truncated": truncated}, nil
+	})
+}
+
+// searchStartDepth returns the depth offset to subtract from each
+// visited entry's root-relative depth, so maxDepth counts from start
+// rather than from the workspace root: 0 when start is the root itself,
+// otherwise start's own depth below the root.
+func searchStartDepth(root, start string) int {
+	rel, err := filepath.Rel(root, start)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
+
+// detectMimeType guesses a file's MIME type from its extension first,
+// falling back to sniffing the first 512 bytes like http.DetectContentType.
+func detectMimeType(path string, info fs.FileInfo) string {
+	if ext := filepath.Ext(path); ext != "" {
+		if t := mime.TypeByExtension(ext); t != "" {
+			return strings.SplitN(t, ";", 2)[0]
+		}
+	}
+	if info.Size() == 0 {
+		return "application/octet-stream"
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, _ := f.Read(buf)
+	return http.DetectContentType(buf[:n])
+}
+
+// ignoreMatcher holds the .mcpignore/.gitignore style patterns loaded
+// from a workspace root.
+type ignoreMatcher struct {
+	patterns []string
+}
+
+// loadIgnoreMatcher reads ignore patterns from .mcpignore and .gitignore
+// at the workspace root, preferring .mcpignore when both are present.
+func loadIgnoreMatcher(root string) *ignoreMatcher {
+	for _, name := range []string{".mcpignore", ".gitignore"} {
+		patterns, err := readIgnoreFile(filepath.Join(root, name))
+		if err == nil {
+			return &ignoreMatcher{patterns: patterns}
+		}
+	}
+	return &ignoreMatcher{}
+}
+
+func readIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// Match reports whether relSlash (forward-slash separated, relative to
+// the workspace root) is excluded by any loaded pattern. Matching is
+// doublestar-style rather than full gitignore fidelity: a pattern
+// matches at any depth and, for directories, everything beneath it.
+func (m *ignoreMatcher) Match(relSlash string, isDir bool) bool {
+	for _, p := range m.patterns {
+		pattern := strings.TrimSuffix(p, "/")
+		candidates := []string{
+			pattern,
+			pattern + "/**",
+			"**/" + pattern,
+			"**/" + pattern + "/**",
+		}
+		for _, cand := range candidates {
+			if ok, _ := doublestar.Match(cand, relSlash); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
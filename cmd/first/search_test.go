@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchStartDepth_RootItself(t *testing.T) {
+	root := filepath.Join(string(os.PathSeparator), "home", "user", "project")
+	if got := searchStartDepth(root, root); got != 0 {
+		t.Fatalf("searchStartDepth(root, root) = %d, want 0", got)
+	}
+}
+
+func TestSearchStartDepth_Subdirectory(t *testing.T) {
+	root := filepath.Join(string(os.PathSeparator), "home", "user", "project")
+	start := filepath.Join(root, "src", "pkg")
+	if got := searchStartDepth(root, start); got != 2 {
+		t.Fatalf("searchStartDepth(root, src/pkg) = %d, want 2", got)
+	}
+}
+
+func TestIgnoreMatcher_Match(t *testing.T) {
+	m := &ignoreMatcher{patterns: []string{"*.log", "node_modules", "build"}}
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"debug.log", true},
+		{"src/debug.log", true},
+		{"node_modules/left-pad/index.js", true},
+		{"build/out.bin", true},
+		{"src/build/out.bin", true},
+		{"src/main.go", false},
+	}
+
+	for _, c := range cases {
+		if got := m.Match(c.path, false); got != c.want {
+			t.Errorf("Match(%q) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMaxReadBytes caps how much of a file read_file will return when
+// the caller doesn't set MaxBytes, so a single call can't accidentally
+// buffer an enormous file into memory.
+const defaultMaxReadBytes = 1 << 20 // 1 MiB
+
+// hardMaxReadBytes is the ceiling read_file clamps a caller-supplied
+// MaxBytes to, so a client can't force an unbounded allocation by simply
+// asking for more.
+const hardMaxReadBytes = 64 << 20 // 64 MiB
+
+type ReadFileParams struct {
+	Root     string `json:"root,omitempty"`
+	Path     string `json:"path"`
+	Offset   int64  `json:"offset,omitempty"`
+	Length   int64  `json:"length,omitempty"`   // 0 means "to end, subject to MaxBytes"
+	MaxBytes int64  `json:"maxBytes,omitempty"` // 0 means defaultMaxReadBytes
+	Encoding string `json:"encoding,omitempty"` // "text" (default) or "base64"
+}
+
+type WriteFileParams struct {
+	Root       string `json:"root,omitempty"`
+	Path       string `json:"path"`
+	Content    string `json:"content"`
+	Encoding   string `json:"encoding,omitempty"` // "text" (default) or "base64"
+	CreateOnly bool   `json:"createOnly,omitempty"`
+}
+
+type MkdirParams struct {
+	Root string `json:"root,omitempty"`
+	Path string `json:"path"`
+}
+
+type DeleteParams struct {
+	Root      string `json:"root,omitempty"`
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive,omitempty"`
+}
+
+type MoveParams struct {
+	Root string `json:"root,omitempty"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// registerFsTools registers read_file, write_file, mkdir, delete, and
+// move, each gated by policy.ToolEnabled so a read-only deployment (the
+// default with no policy file) only exposes read_file.
+func registerFsTools(srv *mcp.Server, workspaces *WorkspaceSet, policy *Policy) {
+	if policy.ToolEnabled("read_file") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "read_file",
+			Description: "Read a file under a configured root. Args: { root?, path, offset?, length?, maxBytes?, encoding? }",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args ReadFileParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			target, err := ws.ResolveStrict("read_file", args.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			f, err := os.Open(target)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer f.Close()
+
+			if args.Offset > 0 {
+				if _, err := f.Seek(args.Offset, io.SeekStart); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			maxBytes := clampMaxReadBytes(args.MaxBytes)
+			readLen := maxBytes
+			if args.Length > 0 && args.Length < maxBytes {
+				readLen = args.Length
+			}
+
+			data := make([]byte, readLen)
+			n, err := io.ReadFull(f, data)
+			if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+				return nil, nil, err
+			}
+			data = data[:n]
+
+			encoding := args.Encoding
+			if encoding == "" {
+				encoding = "text"
+			}
+
+			var text string
+			switch encoding {
+			case "text":
+				text = string(data)
+			case "base64":
+				text = base64.StdEncoding.EncodeToString(data)
+			default:
+				return nil, nil, fmt.Errorf("unknown encoding %q: want %q or %q", encoding, "text", "base64")
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: text}},
+			}, map[string]any{"bytesRead": n, "encoding": encoding}, nil
+		})
+	}
+
+	if policy.ToolEnabled("write_file") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "write_file",
+			Description: "Write a file under a configured root, atomically. Args: { root?, path, content, encoding?, createOnly? }",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args WriteFileParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			target, err := ws.ResolveStrict("write_file", args.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			encoding := args.Encoding
+			if encoding == "" {
+				encoding = "text"
+			}
+
+			var data []byte
+			switch encoding {
+			case "text":
+				data = []byte(args.Content)
+			case "base64":
+				data, err = base64.StdEncoding.DecodeString(args.Content)
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid base64 content: %w", err)
+				}
+			default:
+				return nil, nil, fmt.Errorf("unknown encoding %q: want %q or %q", encoding, "text", "base64")
+			}
+
+			if args.CreateOnly {
+				if _, err := os.Lstat(target); err == nil {
+					return nil, nil, fmt.Errorf("refusing to overwrite existing file: %q", args.Path)
+				} else if !os.IsNotExist(err) {
+					return nil, nil, err
+				}
+			}
+
+			if err := atomicWriteFile(target, data, 0o644); err != nil {
+				return nil, nil, err
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Wrote %d bytes to %s", len(data), ws.RelOrSame(target))}},
+			}, map[string]any{"bytesWritten": len(data)}, nil
+		})
+	}
+
+	if policy.ToolEnabled("mkdir") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "mkdir",
+			Description: "Create a directory (and any missing parents) under a configured root. Args: { root?, path }",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args MkdirParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			target, err := ws.MkdirAllInRoot("mkdir", args.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Created %s", ws.RelOrSame(target))}},
+			}, nil, nil
+		})
+	}
+
+	if policy.ToolEnabled("delete") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "delete",
+			Description: "Delete a file or directory under a configured root. Args: { root?, path, recursive? }",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args DeleteParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			target, err := ws.ResolveStrict("delete", args.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			if target == ws.Root {
+				return nil, nil, fmt.Errorf("refusing to delete the root itself")
+			}
+
+			if args.Recursive {
+				err = os.RemoveAll(target)
+			} else {
+				err = os.Remove(target)
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Deleted %s", ws.RelOrSame(target))}},
+			}, nil, nil
+		})
+	}
+
+	if policy.ToolEnabled("move") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "move",
+			Description: "Move or rename a file or directory under a configured root. Args: { root?, from, to }",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args MoveParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			src, err := ws.ResolveStrict("move", args.From)
+			if err != nil {
+				return nil, nil, err
+			}
+			dst, err := ws.ResolveStrict("move", args.To)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := os.Rename(src, dst); err != nil {
+				return nil, nil, err
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Moved %s to %s", ws.RelOrSame(src), ws.RelOrSame(dst))}},
+			}, nil, nil
+		})
+	}
+}
+
+// clampMaxReadBytes resolves a caller-supplied MaxBytes into the limit
+// read_file actually uses: defaultMaxReadBytes when unset, otherwise the
+// caller's value clamped to hardMaxReadBytes so it can't force an
+// unbounded allocation.
+func clampMaxReadBytes(requested int64) int64 {
+	if requested <= 0 {
+		return defaultMaxReadBytes
+	}
+	if requested > hardMaxReadBytes {
+		return hardMaxReadBytes
+	}
+	return requested
+}
+
+// atomicWriteFile writes data to a temp file in the same directory as
+// path and renames it into place, so readers never observe a partial
+// write.
+func atomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
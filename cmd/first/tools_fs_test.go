@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAtomicWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+
+	if err := atomicWriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("atomicWriteFile: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Fatalf("got %q, want %q", got, "hello")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	for _, e := range entries {
+		if e.Name() != "out.txt" {
+			t.Fatalf("leftover temp file: %q", e.Name())
+		}
+	}
+}
+
+func TestClampMaxReadBytes(t *testing.T) {
+	cases := []struct {
+		requested int64
+		want      int64
+	}{
+		{0, defaultMaxReadBytes},
+		{-1, defaultMaxReadBytes},
+		{1024, 1024},
+		{hardMaxReadBytes, hardMaxReadBytes},
+		{hardMaxReadBytes * 100, hardMaxReadBytes},
+	}
+	for _, c := range cases {
+		if got := clampMaxReadBytes(c.requested); got != c.want {
+			t.Errorf("clampMaxReadBytes(%d) = %d, want %d", c.requested, got, c.want)
+		}
+	}
+}
+
+func TestReadWrite_RejectSymlinkEscape(t *testing.T) {
+	ws := newTestWorkspace(t)
+	outside := t.TempDir()
+
+	if err := os.Symlink(outside, filepath.Join(ws.Root, "escape")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	if _, err := ws.ResolveStrict("read_file", filepath.Join("escape", "secret.txt")); err == nil {
+		t.Fatal("expected read/write path resolution to reject a symlink escape, got nil")
+	}
+	if _, err := ws.ResolveStrict("write_file", filepath.Join("escape", "secret.txt")); err == nil {
+		t.Fatal("expected read/write path resolution to reject a symlink escape, got nil")
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const fileURIScheme = "file"
+
+// PathToURI converts an absolute OS path into a file:// resource URI,
+// normalizing separators to forward slashes (via filepath.ToSlash) so
+// Windows clients see the same URI shape as everyone else. root is
+// accepted for symmetry with URIToPath; the URI always encodes the full
+// absolute path rather than one relative to a workspace, so resources
+// from different workspaces never collide.
+func PathToURI(root, abs string) string {
+	_ = root
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	u := url.URL{Scheme: fileURIScheme, Path: p}
+	return u.String()
+}
+
+// URIToPath converts a file:// resource URI back into an OS path,
+// reversing PathToURI's normalization (including the Windows drive-path
+// convention of "/C:/Users/...").
+func URIToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource URI %q: %w", uri, err)
+	}
+	if u.Scheme != fileURIScheme {
+		return "", fmt.Errorf("unsupported URI scheme %q: want %q", u.Scheme, fileURIScheme)
+	}
+	p := u.Path
+	if len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:] // "/C:/Users" -> "C:/Users"
+	}
+	return filepath.FromSlash(p), nil
+}
+
+// IsResourceURI reports whether p looks like a file:// resource URI
+// rather than a plain, workspace-relative path.
+func IsResourceURI(p string) bool {
+	return strings.HasPrefix(p, fileURIScheme+"://")
+}
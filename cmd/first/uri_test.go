@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestPathToURI_RoundTrip(t *testing.T) {
+	abs := "/home/me/proj/notes.md"
+	uri := PathToURI("", abs)
+	if uri != "file:///home/me/proj/notes.md" {
+		t.Fatalf("got %q, want %q", uri, "file:///home/me/proj/notes.md")
+	}
+
+	got, err := URIToPath(uri)
+	if err != nil {
+		t.Fatalf("URIToPath: %v", err)
+	}
+	if got != abs {
+		t.Fatalf("got %q, want %q", got, abs)
+	}
+}
+
+func TestURIToPath_WindowsDrive(t *testing.T) {
+	got, err := URIToPath("file:///C:/Users/me/notes.md")
+	if err != nil {
+		t.Fatalf("URIToPath: %v", err)
+	}
+	if got != "C:/Users/me/notes.md" && got != `C:\Users\me\notes.md` {
+		t.Fatalf("got %q, want a path rooted at C:", got)
+	}
+}
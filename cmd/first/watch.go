@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// notifyDebounce coalesces bursts of fsnotify events (e.g. an editor
+// doing write-then-rename) into a single resources/updated notification.
+const notifyDebounce = 300 * time.Millisecond
+
+type WatchParams struct {
+	Root string `json:"root,omitempty"`
+	Path string `json:"path,omitempty"` // subdirectory to watch; defaults to the whole root
+}
+
+// resourceWatcher watches every configured workspace recursively with
+// fsnotify and turns filesystem events into debounced MCP
+// notifications/resources/updated messages. It also backs the
+// watch/unwatch tools, which register coarse, tool-driven subscriptions
+// on top of the same fsnotify plumbing used for protocol subscriptions.
+type resourceWatcher struct {
+	srv        *mcp.Server
+	workspaces *WorkspaceSet
+
+	mu            sync.Mutex
+	watchers      map[string]*fsnotify.Watcher // workspace name -> watcher
+	subscribed    map[string]bool              // resource URI -> protocol-subscribed
+	coarse        map[string]bool              // resource URI -> tool-driven watch
+	pendingTimers map[string]*time.Timer       // resource URI -> debounce timer
+}
+
+func newResourceWatcher(srv *mcp.Server, workspaces *WorkspaceSet) *resourceWatcher {
+	return &resourceWatcher{
+		srv:           srv,
+		workspaces:    workspaces,
+		watchers:      make(map[string]*fsnotify.Watcher),
+		subscribed:    make(map[string]bool),
+		coarse:        make(map[string]bool),
+		pendingTimers: make(map[string]*time.Timer),
+	}
+}
+
+// Start begins watching every configured workspace root. It returns an
+// error only if a watcher for a root cannot be created at all; per-file
+// errors are logged and otherwise ignored so one bad root doesn't take
+// down the others.
+func (rw *resourceWatcher) Start() error {
+	for _, name := range rw.workspaces.Names() {
+		ws, err := rw.workspaces.Get(name)
+		if err != nil {
+			return err
+		}
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("creating watcher for root %q: %w", name, err)
+		}
+		if err := addRecursive(w, ws.Root); err != nil {
+			return fmt.Errorf("watching root %q: %w", name, err)
+		}
+		rw.mu.Lock()
+		rw.watchers[name] = w
+		rw.mu.Unlock()
+		go rw.loop(ws, w)
+	}
+	return nil
+}
+
+func addRecursive(w *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("watch: skipping %q: %v", path, err)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err := w.Add(path); err != nil {
+				log.Printf("watch: failed to watch %q: %v", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (rw *resourceWatcher) loop(ws *Workspace, w *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.Add(event.Name); err != nil {
+						log.Printf("watch: failed to add new directory %q: %v", event.Name, err)
+					}
+				}
+			}
+			rw.scheduleNotify(ws, event.Name)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("watch: fsnotify error for root %q: %v", ws.Name, err)
+		}
+	}
+}
+
+// scheduleNotify debounces notifications per-path so a burst of events
+// for the same file collapses into a single update.
+func (rw *resourceWatcher) scheduleNotify(ws *Workspace, path string) {
+	uri := ws.URI(path)
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if !rw.subscribed[uri] && !rw.underCoarseWatchLocked(ws, path) {
+		return
+	}
+
+	if t, ok := rw.pendingTimers[uri]; ok {
+		t.Stop()
+	}
+	rw.pendingTimers[uri] = time.AfterFunc(notifyDebounce, func() {
+		rw.mu.Lock()
+		delete(rw.pendingTimers, uri)
+		rw.mu.Unlock()
+		rw.notify(uri)
+	})
+}
+
+func (rw *resourceWatcher) underCoarseWatchLocked(ws *Workspace, path string) bool {
+	for uri := range rw.coarse {
+		watchedPath, err := URIToPath(uri)
+		if err != nil {
+			continue
+		}
+		if path == watchedPath || filepath.Dir(path) == watchedPath {
+			return true
+		}
+		if rel, err := filepath.Rel(watchedPath, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rw *resourceWatcher) notify(uri string) {
+	ctx := context.Background()
+	if err := rw.srv.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+		log.Printf("watch: failed to send notifications/resources/updated for %s: %v", uri, err)
+	}
+}
+
+func registerWatchTools(srv *mcp.Server, workspaces *WorkspaceSet, rw *resourceWatcher, policy *Policy) {
+	if policy.ToolEnabled("watch") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "watch",
+			Description: "Subscribe to change notifications for a subdirectory of a configured root. Args: { root?, path? }",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args WatchParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			target, err := ws.Resolve("watch", args.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			uri := ws.URI(target)
+
+			rw.mu.Lock()
+			rw.coarse[uri] = true
+			rw.mu.Unlock()
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Watching %s", ws.RelOrSame(target))}},
+			}, map[string]any{"uri": uri}, nil
+		})
+	}
+
+	if policy.ToolEnabled("unwatch") {
+		mcp.AddTool(srv, &mcp.Tool{
+			Name:        "unwatch",
+			Description: "Remove a watch previously registered with the watch tool. Args: { root?, path? }",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, args WatchParams) (*mcp.CallToolResult, interface{}, error) {
+			ws, err := workspaces.Get(args.Root)
+			if err != nil {
+				return nil, nil, err
+			}
+			target, err := ws.Resolve("unwatch", args.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			uri := ws.URI(target)
+
+			rw.mu.Lock()
+			delete(rw.coarse, uri)
+			rw.mu.Unlock()
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Stopped watching %s", ws.RelOrSame(target))}},
+			}, nil, nil
+		})
+	}
+}
+
+// registerResources exposes every configured workspace root as an MCP
+// resource so clients can browse and subscribe to it, backed by the
+// same fsnotify watcher that powers the watch/unwatch tools. Resources
+// are only registered when list_resources is enabled by policy.
+func registerResources(srv *mcp.Server, workspaces *WorkspaceSet, rw *resourceWatcher, policy *Policy) {
+	if !policy.ToolEnabled("list_resources") {
+		return
+	}
+	for _, name := range workspaces.Names() {
+		ws, err := workspaces.Get(name)
+		if err != nil {
+			continue
+		}
+		srv.AddResource(&mcp.Resource{
+			URI:         ws.URI(ws.Root),
+			Name:        ws.Name,
+			Description: fmt.Sprintf("Workspace root %q", ws.Name),
+			MIMEType:    "text/directory",
+		}, rw.readResource)
+	}
+}
+
+// readResource backs every registered workspace resource: a directory
+// URI returns its entry names, a file URI returns its content. The URI
+// is resolved through the owning Workspace first, with the containment
+// check and the read_file policy, rather than touching the filesystem
+// directly. Directory URIs are listed without symlink resolution; file
+// URIs are re-resolved with ResolveStrict, as read_file does, before
+// their content is read.
+func (rw *resourceWatcher) readResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	ws, path, err := rw.workspaces.ResolveResourceURI("read_file", req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: req.Params.URI, MIMEType: "text/directory", Text: strings.Join(names, "\n")}},
+		}, nil
+	}
+
+	// Re-resolve with the symlink-safe check read_file itself uses before
+	// touching file content (ResolveResourceURI above only applies the
+	// plain containment+policy check, same as list_resources).
+	path, err = ws.ResolveStrict("read_file", req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: req.Params.URI, MIMEType: detectMimeType(path, info), Text: string(data)}},
+	}, nil
+}
+
+func (rw *resourceWatcher) subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.subscribed[req.Params.URI] = true
+	return nil
+}
+
+func (rw *resourceWatcher) unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	delete(rw.subscribed, req.Params.URI)
+	return nil
+}
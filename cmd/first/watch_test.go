@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestURIToPath(t *testing.T) {
+	path, err := URIToPath("file:///srv/docs/readme.md")
+	if err != nil {
+		t.Fatalf("URIToPath: %v", err)
+	}
+	if path != "/srv/docs/readme.md" {
+		t.Fatalf("got %q, want %q", path, "/srv/docs/readme.md")
+	}
+
+	if _, err := URIToPath("https://example.com/readme.md"); err == nil {
+		t.Fatal("expected error for a non-file:// URI")
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Workspace is a single named root directory that tools resolve paths
+// against. Every tool that touches the filesystem goes through
+// Workspace.Resolve so containment is enforced in exactly one place.
+type Workspace struct {
+	Name   string
+	Root   string
+	Policy *Policy
+}
+
+// NewWorkspace builds a Workspace rooted at dir, resolving it to an
+// absolute path.
+func NewWorkspace(name, dir string) (*Workspace, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve root %q: %w", dir, err)
+	}
+	return &Workspace{Name: name, Root: abs, Policy: defaultPolicy()}, nil
+}
+
+// Resolve accepts either a path relative to the workspace root or a
+// file:// resource URI, joins it onto the root as needed, and verifies
+// the result does not escape the root and is allowed by policy for
+// tool, returning the absolute path. Every tool that touches the
+// filesystem must call this (or ResolveStrict/MkdirAllInRoot, which
+// build on it) so containment and policy enforcement stay in one place.
+func (w *Workspace) Resolve(tool, p string) (string, error) {
+	if IsResourceURI(p) {
+		path, err := URIToPath(p)
+		if err != nil {
+			return "", err
+		}
+		return w.resolveAbs(tool, path, p)
+	}
+	if p == "" || p == "." {
+		p = ""
+	}
+	return w.resolveAbs(tool, filepath.Join(w.Root, p), p)
+}
+
+func (w *Workspace) resolveAbs(tool, joined, original string) (string, error) {
+	abs, err := filepath.Abs(joined)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(w.Root, abs)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("path outside root: %q", original)
+	}
+	if err := w.Policy.Check(tool, filepath.ToSlash(rel), abs); err != nil {
+		return "", err
+	}
+	return abs, nil
+}
+
+// URI returns abs (which must be under the workspace root) as a file://
+// resource URI.
+func (w *Workspace) URI(abs string) string {
+	return PathToURI(w.Root, abs)
+}
+
+// ResolveStrict behaves like Resolve but additionally evaluates symlinks
+// on the target itself (or, if it doesn't exist yet, its parent
+// directory) and re-verifies containment, so a symlink planted inside
+// the root — whether a symlinked file or a symlinked parent directory —
+// can't be used to escape it. It returns the symlink-resolved path.
+// Tools that read, write, or delete file content should use this
+// instead of Resolve.
+func (w *Workspace) ResolveStrict(tool, p string) (string, error) {
+	abs, err := w.Resolve(tool, p)
+	if err != nil {
+		return "", err
+	}
+
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		if err := w.checkRealContainment(real, p); err != nil {
+			return "", err
+		}
+		return real, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	// abs doesn't exist yet (e.g. write_file creating a new file), so
+	// there's nothing to resolve at the leaf; verify the parent directory
+	// doesn't escape the root via a symlink instead.
+	parent := filepath.Dir(abs)
+	real, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Parent doesn't exist yet either; verify the nearest existing
+			// ancestor instead, so a symlinked ancestor can't escape the root.
+			for dir := filepath.Dir(parent); ; dir = filepath.Dir(dir) {
+				realDir, dirErr := filepath.EvalSymlinks(dir)
+				if dirErr == nil {
+					if err := w.checkRealContainment(realDir, p); err != nil {
+						return "", err
+					}
+					return abs, nil
+				}
+				if !os.IsNotExist(dirErr) || dir == filepath.Dir(dir) {
+					return "", dirErr
+				}
+			}
+		}
+		return "", err
+	}
+	if err := w.checkRealContainment(real, p); err != nil {
+		return "", err
+	}
+	return filepath.Join(real, filepath.Base(abs)), nil
+}
+
+// checkRealContainment verifies that real (a symlink-resolved absolute
+// path) is still under the workspace root, so a symlink swapped in
+// after Resolve's initial containment check can't redirect a tool
+// outside the root.
+func (w *Workspace) checkRealContainment(real, original string) error {
+	rel, err := filepath.Rel(w.Root, real)
+	if err != nil {
+		return err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return fmt.Errorf("path escapes root via symlink: %q", original)
+	}
+	return nil
+}
+
+// MkdirAllInRoot creates p (relative to the workspace root) and any
+// missing parent directories, resolving and re-verifying containment of
+// every path component as it goes — analogous to runc's
+// MkdirAllInRoot — so a symlink swapped in mid-walk can't redirect
+// creation outside the root.
+func (w *Workspace) MkdirAllInRoot(tool, p string) (string, error) {
+	abs, err := w.Resolve(tool, p)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(w.Root, abs)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." {
+		return abs, nil
+	}
+
+	cur := w.Root
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		cur = filepath.Join(cur, part)
+		info, err := os.Lstat(cur)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return "", err
+			}
+			if err := os.Mkdir(cur, 0o755); err != nil && !os.IsExist(err) {
+				return "", err
+			}
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(cur)
+			if err != nil {
+				return "", fmt.Errorf("cannot resolve symlink %q: %w", cur, err)
+			}
+			relTarget, err := filepath.Rel(w.Root, target)
+			if err != nil || relTarget == ".." || strings.HasPrefix(relTarget, ".."+string(os.PathSeparator)) {
+				return "", fmt.Errorf("path escapes root via symlink: %q", cur)
+			}
+			continue
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("%q exists and is not a directory", cur)
+		}
+	}
+	return abs, nil
+}
+
+// RelOrSame returns p relative to the workspace root, or p itself if it
+// can't be made relative (or is the root itself).
+func (w *Workspace) RelOrSame(p string) string {
+	if r, err := filepath.Rel(w.Root, p); err == nil && r != "." {
+		return r
+	}
+	return p
+}
+
+// WorkspaceSet is the collection of named roots the server was started
+// with, keyed by name. A server always has at least one workspace,
+// named "default" unless MCP_FS_ROOTS assigns it another name.
+type WorkspaceSet struct {
+	workspaces map[string]*Workspace
+	order      []string
+}
+
+func newWorkspaceSet() *WorkspaceSet {
+	return &WorkspaceSet{workspaces: make(map[string]*Workspace)}
+}
+
+func (s *WorkspaceSet) add(ws *Workspace) {
+	if _, exists := s.workspaces[ws.Name]; !exists {
+		s.order = append(s.order, ws.Name)
+	}
+	s.workspaces[ws.Name] = ws
+}
+
+// Get returns the named workspace, or the sole/default workspace when
+// name is empty.
+func (s *WorkspaceSet) Get(name string) (*Workspace, error) {
+	if name == "" {
+		if ws, ok := s.workspaces["default"]; ok {
+			return ws, nil
+		}
+		if len(s.order) == 1 {
+			return s.workspaces[s.order[0]], nil
+		}
+		return nil, fmt.Errorf("root argument required: multiple workspaces configured (%s)", strings.Join(s.Names(), ", "))
+	}
+	ws, ok := s.workspaces[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown root %q: configured roots are %s", name, strings.Join(s.Names(), ", "))
+	}
+	return ws, nil
+}
+
+// SetPolicy attaches p to every workspace in the set, so all of them
+// enforce the same tool/path policy.
+func (s *WorkspaceSet) SetPolicy(p *Policy) {
+	for _, ws := range s.workspaces {
+		ws.Policy = p
+	}
+}
+
+// ResolveResourceURI resolves a file:// resource URI against whichever
+// configured workspace contains it, applying that workspace's
+// containment check and policy for tool exactly as Resolve would for a
+// tool argument. A URI carries no workspace name of its own, so every
+// workspace is tried in turn; the first to accept the URI (i.e. it falls
+// under that workspace's root and policy allows tool there) wins. This
+// is what the resources/read handler uses so a bare file:// URI can't be
+// used to read outside every configured root or around policy.
+func (s *WorkspaceSet) ResolveResourceURI(tool, uri string) (*Workspace, string, error) {
+	var err error
+	for _, name := range s.Names() {
+		ws := s.workspaces[name]
+		var abs string
+		if abs, err = ws.Resolve(tool, uri); err == nil {
+			return ws, abs, nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no configured root contains %q", uri)
+	}
+	return nil, "", err
+}
+
+// Names returns the configured workspace names in sorted order, so
+// listings and error messages are stable regardless of the order roots
+// were declared in.
+func (s *WorkspaceSet) Names() []string {
+	names := make([]string, len(s.order))
+	copy(names, s.order)
+	sort.Strings(names)
+	return names
+}
+
+// loadWorkspaces builds a WorkspaceSet from MCP_FS_ROOTS (a comma
+// separated list of name=path pairs) and/or MCP_FS_ROOT (a single,
+// unnamed path kept for backwards compatibility). If neither is set,
+// the current working directory is used as the sole "default" root.
+func loadWorkspaces() (*WorkspaceSet, error) {
+	set := newWorkspaceSet()
+
+	if raw := os.Getenv("MCP_FS_ROOTS"); raw != "" {
+		for _, pair := range strings.Split(raw, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			name, dir, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid MCP_FS_ROOTS entry %q: want name=path", pair)
+			}
+			name, dir = strings.TrimSpace(name), strings.TrimSpace(dir)
+			if name == "" || dir == "" {
+				return nil, fmt.Errorf("invalid MCP_FS_ROOTS entry %q: want name=path", pair)
+			}
+			ws, err := NewWorkspace(name, dir)
+			if err != nil {
+				return nil, err
+			}
+			set.add(ws)
+		}
+	}
+
+	if raw := os.Getenv("MCP_FS_ROOT"); raw != "" {
+		ws, err := NewWorkspace("default", raw)
+		if err != nil {
+			return nil, err
+		}
+		set.add(ws)
+	}
+
+	if len(set.order) == 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine working directory: %w", err)
+		}
+		ws, err := NewWorkspace("default", wd)
+		if err != nil {
+			return nil, err
+		}
+		set.add(ws)
+	}
+
+	return set, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestWorkspace builds a Workspace with no policy restrictions, so
+// these tests exercise path containment in isolation from policy.
+func newTestWorkspace(t *testing.T) *Workspace {
+	t.Helper()
+	root := t.TempDir()
+	ws, err := NewWorkspace("test", root)
+	if err != nil {
+		t.Fatalf("NewWorkspace: %v", err)
+	}
+	ws.Policy = nil
+	return ws
+}
+
+func TestResolveStrict_RejectsSymlinkEscape(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(ws.Root, "escape")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	if _, err := ws.ResolveStrict("read_file", filepath.Join("escape", "file.txt")); err == nil {
+		t.Fatal("expected error resolving path through a symlink that escapes the root, got nil")
+	}
+}
+
+func TestResolveStrict_RejectsSymlinkFileEscape(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("top secret"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Symlink(secret, filepath.Join(ws.Root, "innocuous.txt")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	if _, err := ws.ResolveStrict("read_file", "innocuous.txt"); err == nil {
+		t.Fatal("expected error resolving a path whose leaf itself is a symlink escaping the root, got nil")
+	}
+}
+
+func TestResolveStrict_AllowsSymlinkWithinRoot(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	real := filepath.Join(ws.Root, "real")
+	if err := os.Mkdir(real, 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	if err := os.Symlink(real, filepath.Join(ws.Root, "alias")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	got, err := ws.ResolveStrict("read_file", filepath.Join("alias", "file.txt"))
+	if err != nil {
+		t.Fatalf("ResolveStrict: %v", err)
+	}
+	want := filepath.Join(real, "file.txt")
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestResolveStrict_AllowsSymlinkFileWithinRoot(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	real := filepath.Join(ws.Root, "real.txt")
+	if err := os.WriteFile(real, []byte("hi"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Symlink(real, filepath.Join(ws.Root, "alias.txt")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	got, err := ws.ResolveStrict("read_file", "alias.txt")
+	if err != nil {
+		t.Fatalf("ResolveStrict: %v", err)
+	}
+	if got != real {
+		t.Fatalf("got %q, want %q", got, real)
+	}
+}
+
+func TestMkdirAllInRoot_RejectsSymlinkEscape(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(ws.Root, "escape")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	if _, err := ws.MkdirAllInRoot("mkdir", filepath.Join("escape", "nested", "dir")); err == nil {
+		t.Fatal("expected error creating a directory through a symlink that escapes the root, got nil")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "nested")); err == nil {
+		t.Fatal("MkdirAllInRoot created a directory outside the root")
+	}
+}
+
+func TestResolveResourceURI_RejectsURIOutsideEveryRoot(t *testing.T) {
+	ws := newTestWorkspace(t)
+	set := newWorkspaceSet()
+	set.add(ws)
+
+	if _, _, err := set.ResolveResourceURI("read_file", "file:///etc/passwd"); err == nil {
+		t.Fatal("expected error resolving a URI outside every configured root, got nil")
+	}
+}
+
+func TestResolveResourceURI_PicksOwningWorkspace(t *testing.T) {
+	a := newTestWorkspace(t)
+	b := newTestWorkspace(t)
+	a.Name, b.Name = "a", "b"
+	set := newWorkspaceSet()
+	set.add(a)
+	set.add(b)
+
+	if err := os.WriteFile(filepath.Join(b.Root, "notes.md"), []byte("hi"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ws, abs, err := set.ResolveResourceURI("read_file", PathToURI(b.Root, filepath.Join(b.Root, "notes.md")))
+	if err != nil {
+		t.Fatalf("ResolveResourceURI: %v", err)
+	}
+	if ws != b {
+		t.Fatalf("resolved to workspace %q, want %q", ws.Name, b.Name)
+	}
+	want := filepath.Join(b.Root, "notes.md")
+	if abs != want {
+		t.Fatalf("got %q, want %q", abs, want)
+	}
+}
+
+func TestResolveResourceURI_DeniesByPolicy(t *testing.T) {
+	ws := newTestWorkspace(t)
+	ws.Policy = &Policy{Tools: map[string]ToolPolicy{"read_file": {Deny: []string{"**"}}}}
+	set := newWorkspaceSet()
+	set.add(ws)
+
+	if _, _, err := set.ResolveResourceURI("read_file", PathToURI(ws.Root, filepath.Join(ws.Root, "secret.txt"))); err == nil {
+		t.Fatal("expected policy denial resolving a denied path, got nil")
+	}
+}
+
+func TestResolveResourceURI_DefaultPolicyDeniesOutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	ws, err := NewWorkspace("test", root)
+	if err != nil {
+		t.Fatalf("NewWorkspace: %v", err)
+	}
+	set := newWorkspaceSet()
+	set.add(ws)
+
+	if _, _, err := set.ResolveResourceURI("read_file", "file:///etc/passwd"); err == nil {
+		t.Fatal("expected a resources/read URI outside the root to be rejected under the default read-only policy, got nil")
+	}
+}
+
+func TestMkdirAllInRoot_CreatesNestedDirs(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	abs, err := ws.MkdirAllInRoot("mkdir", filepath.Join("a", "b", "c"))
+	if err != nil {
+		t.Fatalf("MkdirAllInRoot: %v", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if !info.IsDir() {
+		t.Fatalf("%q is not a directory", abs)
+	}
+}